@@ -0,0 +1,357 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package alertsfakes
+
+import (
+	"sync"
+
+	"github.com/hemajv/alertmanager-github-receiver/alerts"
+)
+
+type FakeClient struct {
+	CloseIssueStub        func(*alerts.Issue) (*alerts.Issue, error)
+	closeIssueMutex       sync.RWMutex
+	closeIssueArgsForCall []struct {
+		arg1 *alerts.Issue
+	}
+	closeIssueReturns struct {
+		result1 *alerts.Issue
+		result2 error
+	}
+	closeIssueReturnsOnCall map[int]struct {
+		result1 *alerts.Issue
+		result2 error
+	}
+	CommentOnIssueStub        func(*alerts.Issue, string) (*alerts.Comment, error)
+	commentOnIssueMutex       sync.RWMutex
+	commentOnIssueArgsForCall []struct {
+		arg1 *alerts.Issue
+		arg2 string
+	}
+	commentOnIssueReturns struct {
+		result1 *alerts.Comment
+		result2 error
+	}
+	commentOnIssueReturnsOnCall map[int]struct {
+		result1 *alerts.Comment
+		result2 error
+	}
+	CreateIssueStub        func(string, string, string, []string) (*alerts.Issue, error)
+	createIssueMutex       sync.RWMutex
+	createIssueArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 string
+		arg4 []string
+	}
+	createIssueReturns struct {
+		result1 *alerts.Issue
+		result2 error
+	}
+	createIssueReturnsOnCall map[int]struct {
+		result1 *alerts.Issue
+		result2 error
+	}
+	ListOpenIssuesStub        func() ([]*alerts.Issue, error)
+	listOpenIssuesMutex       sync.RWMutex
+	listOpenIssuesArgsForCall []struct {
+	}
+	listOpenIssuesReturns struct {
+		result1 []*alerts.Issue
+		result2 error
+	}
+	listOpenIssuesReturnsOnCall map[int]struct {
+		result1 []*alerts.Issue
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeClient) CloseIssue(arg1 *alerts.Issue) (*alerts.Issue, error) {
+	fake.closeIssueMutex.Lock()
+	ret, specificReturn := fake.closeIssueReturnsOnCall[len(fake.closeIssueArgsForCall)]
+	fake.closeIssueArgsForCall = append(fake.closeIssueArgsForCall, struct {
+		arg1 *alerts.Issue
+	}{arg1})
+	stub := fake.CloseIssueStub
+	fakeReturns := fake.closeIssueReturns
+	fake.recordInvocation("CloseIssue", []interface{}{arg1})
+	fake.closeIssueMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeClient) CloseIssueCallCount() int {
+	fake.closeIssueMutex.RLock()
+	defer fake.closeIssueMutex.RUnlock()
+	return len(fake.closeIssueArgsForCall)
+}
+
+func (fake *FakeClient) CloseIssueCalls(stub func(*alerts.Issue) (*alerts.Issue, error)) {
+	fake.closeIssueMutex.Lock()
+	defer fake.closeIssueMutex.Unlock()
+	fake.CloseIssueStub = stub
+}
+
+func (fake *FakeClient) CloseIssueArgsForCall(i int) *alerts.Issue {
+	fake.closeIssueMutex.RLock()
+	defer fake.closeIssueMutex.RUnlock()
+	argsForCall := fake.closeIssueArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeClient) CloseIssueReturns(result1 *alerts.Issue, result2 error) {
+	fake.closeIssueMutex.Lock()
+	defer fake.closeIssueMutex.Unlock()
+	fake.CloseIssueStub = nil
+	fake.closeIssueReturns = struct {
+		result1 *alerts.Issue
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) CloseIssueReturnsOnCall(i int, result1 *alerts.Issue, result2 error) {
+	fake.closeIssueMutex.Lock()
+	defer fake.closeIssueMutex.Unlock()
+	fake.CloseIssueStub = nil
+	if fake.closeIssueReturnsOnCall == nil {
+		fake.closeIssueReturnsOnCall = make(map[int]struct {
+			result1 *alerts.Issue
+			result2 error
+		})
+	}
+	fake.closeIssueReturnsOnCall[i] = struct {
+		result1 *alerts.Issue
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) CommentOnIssue(arg1 *alerts.Issue, arg2 string) (*alerts.Comment, error) {
+	fake.commentOnIssueMutex.Lock()
+	ret, specificReturn := fake.commentOnIssueReturnsOnCall[len(fake.commentOnIssueArgsForCall)]
+	fake.commentOnIssueArgsForCall = append(fake.commentOnIssueArgsForCall, struct {
+		arg1 *alerts.Issue
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.CommentOnIssueStub
+	fakeReturns := fake.commentOnIssueReturns
+	fake.recordInvocation("CommentOnIssue", []interface{}{arg1, arg2})
+	fake.commentOnIssueMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeClient) CommentOnIssueCallCount() int {
+	fake.commentOnIssueMutex.RLock()
+	defer fake.commentOnIssueMutex.RUnlock()
+	return len(fake.commentOnIssueArgsForCall)
+}
+
+func (fake *FakeClient) CommentOnIssueCalls(stub func(*alerts.Issue, string) (*alerts.Comment, error)) {
+	fake.commentOnIssueMutex.Lock()
+	defer fake.commentOnIssueMutex.Unlock()
+	fake.CommentOnIssueStub = stub
+}
+
+func (fake *FakeClient) CommentOnIssueArgsForCall(i int) (*alerts.Issue, string) {
+	fake.commentOnIssueMutex.RLock()
+	defer fake.commentOnIssueMutex.RUnlock()
+	argsForCall := fake.commentOnIssueArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeClient) CommentOnIssueReturns(result1 *alerts.Comment, result2 error) {
+	fake.commentOnIssueMutex.Lock()
+	defer fake.commentOnIssueMutex.Unlock()
+	fake.CommentOnIssueStub = nil
+	fake.commentOnIssueReturns = struct {
+		result1 *alerts.Comment
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) CommentOnIssueReturnsOnCall(i int, result1 *alerts.Comment, result2 error) {
+	fake.commentOnIssueMutex.Lock()
+	defer fake.commentOnIssueMutex.Unlock()
+	fake.CommentOnIssueStub = nil
+	if fake.commentOnIssueReturnsOnCall == nil {
+		fake.commentOnIssueReturnsOnCall = make(map[int]struct {
+			result1 *alerts.Comment
+			result2 error
+		})
+	}
+	fake.commentOnIssueReturnsOnCall[i] = struct {
+		result1 *alerts.Comment
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) CreateIssue(arg1 string, arg2 string, arg3 string, arg4 []string) (*alerts.Issue, error) {
+	var arg4Copy []string
+	if arg4 != nil {
+		arg4Copy = make([]string, len(arg4))
+		copy(arg4Copy, arg4)
+	}
+	fake.createIssueMutex.Lock()
+	ret, specificReturn := fake.createIssueReturnsOnCall[len(fake.createIssueArgsForCall)]
+	fake.createIssueArgsForCall = append(fake.createIssueArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 string
+		arg4 []string
+	}{arg1, arg2, arg3, arg4Copy})
+	stub := fake.CreateIssueStub
+	fakeReturns := fake.createIssueReturns
+	fake.recordInvocation("CreateIssue", []interface{}{arg1, arg2, arg3, arg4Copy})
+	fake.createIssueMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeClient) CreateIssueCallCount() int {
+	fake.createIssueMutex.RLock()
+	defer fake.createIssueMutex.RUnlock()
+	return len(fake.createIssueArgsForCall)
+}
+
+func (fake *FakeClient) CreateIssueCalls(stub func(string, string, string, []string) (*alerts.Issue, error)) {
+	fake.createIssueMutex.Lock()
+	defer fake.createIssueMutex.Unlock()
+	fake.CreateIssueStub = stub
+}
+
+func (fake *FakeClient) CreateIssueArgsForCall(i int) (string, string, string, []string) {
+	fake.createIssueMutex.RLock()
+	defer fake.createIssueMutex.RUnlock()
+	argsForCall := fake.createIssueArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeClient) CreateIssueReturns(result1 *alerts.Issue, result2 error) {
+	fake.createIssueMutex.Lock()
+	defer fake.createIssueMutex.Unlock()
+	fake.CreateIssueStub = nil
+	fake.createIssueReturns = struct {
+		result1 *alerts.Issue
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) CreateIssueReturnsOnCall(i int, result1 *alerts.Issue, result2 error) {
+	fake.createIssueMutex.Lock()
+	defer fake.createIssueMutex.Unlock()
+	fake.CreateIssueStub = nil
+	if fake.createIssueReturnsOnCall == nil {
+		fake.createIssueReturnsOnCall = make(map[int]struct {
+			result1 *alerts.Issue
+			result2 error
+		})
+	}
+	fake.createIssueReturnsOnCall[i] = struct {
+		result1 *alerts.Issue
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) ListOpenIssues() ([]*alerts.Issue, error) {
+	fake.listOpenIssuesMutex.Lock()
+	ret, specificReturn := fake.listOpenIssuesReturnsOnCall[len(fake.listOpenIssuesArgsForCall)]
+	fake.listOpenIssuesArgsForCall = append(fake.listOpenIssuesArgsForCall, struct {
+	}{})
+	stub := fake.ListOpenIssuesStub
+	fakeReturns := fake.listOpenIssuesReturns
+	fake.recordInvocation("ListOpenIssues", []interface{}{})
+	fake.listOpenIssuesMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeClient) ListOpenIssuesCallCount() int {
+	fake.listOpenIssuesMutex.RLock()
+	defer fake.listOpenIssuesMutex.RUnlock()
+	return len(fake.listOpenIssuesArgsForCall)
+}
+
+func (fake *FakeClient) ListOpenIssuesCalls(stub func() ([]*alerts.Issue, error)) {
+	fake.listOpenIssuesMutex.Lock()
+	defer fake.listOpenIssuesMutex.Unlock()
+	fake.ListOpenIssuesStub = stub
+}
+
+func (fake *FakeClient) ListOpenIssuesReturns(result1 []*alerts.Issue, result2 error) {
+	fake.listOpenIssuesMutex.Lock()
+	defer fake.listOpenIssuesMutex.Unlock()
+	fake.ListOpenIssuesStub = nil
+	fake.listOpenIssuesReturns = struct {
+		result1 []*alerts.Issue
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) ListOpenIssuesReturnsOnCall(i int, result1 []*alerts.Issue, result2 error) {
+	fake.listOpenIssuesMutex.Lock()
+	defer fake.listOpenIssuesMutex.Unlock()
+	fake.ListOpenIssuesStub = nil
+	if fake.listOpenIssuesReturnsOnCall == nil {
+		fake.listOpenIssuesReturnsOnCall = make(map[int]struct {
+			result1 []*alerts.Issue
+			result2 error
+		})
+	}
+	fake.listOpenIssuesReturnsOnCall[i] = struct {
+		result1 []*alerts.Issue
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.closeIssueMutex.RLock()
+	defer fake.closeIssueMutex.RUnlock()
+	fake.commentOnIssueMutex.RLock()
+	defer fake.commentOnIssueMutex.RUnlock()
+	fake.createIssueMutex.RLock()
+	defer fake.createIssueMutex.RUnlock()
+	fake.listOpenIssuesMutex.RLock()
+	defer fake.listOpenIssuesMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeClient) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ alerts.Client = new(FakeClient)
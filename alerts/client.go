@@ -0,0 +1,105 @@
+// Copyright 2017 alertmanager-github-receiver Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//////////////////////////////////////////////////////////////////////////////
+
+// Package alerts turns Alertmanager webhook notifications into issues on a
+// forge (GitHub, GitLab, or Gitea).
+package alerts
+
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 -generate
+
+import (
+	"context"
+	"fmt"
+)
+
+// Issue is a backend-agnostic view of a tracker issue. Each Client
+// implementation translates to and from its backend's native type at the
+// edge, so the rest of the package never has to know which forge it's
+// talking to.
+type Issue struct {
+	Number int
+	Title  string
+	Body   string
+	State  string
+	// Repo identifies the repo (or, for GitLab, the project) the issue
+	// belongs to, in the backend's own routing-key format: the bare repo
+	// name for GitHub and Gitea, "group/project" for GitLab.
+	Repo string
+}
+
+// Comment is a single note left on an Issue.
+type Comment struct {
+	Body string
+}
+
+// Client is the interface ReceiverHandler uses to track alerts as issues on
+// an upstream tracker. It is implemented by GitHubClient, GitLabClient, and
+// GiteaClient below, and by a generated fake (see alertsfakes) in the test
+// suite.
+//
+//counterfeiter:generate -o alertsfakes/fake_client.go . Client
+type Client interface {
+	// ListOpenIssues returns every open issue the receiver has created, so
+	// the handler can match incoming alert groups against them.
+	ListOpenIssues() ([]*Issue, error)
+	// CreateIssue opens a new issue for an alert group that has no open
+	// issue tracking it yet.
+	CreateIssue(repo, title, body string, extraLabels []string) (*Issue, error)
+	// CommentOnIssue appends a comment to an already-open issue, used when
+	// an alert group that is already being tracked fires or resolves
+	// again.
+	CommentOnIssue(issue *Issue, body string) (*Comment, error)
+	// CloseIssue closes an issue whose alert group has resolved.
+	CloseIssue(issue *Issue) (*Issue, error)
+}
+
+// Backend identifies which forge a Client talks to.
+type Backend string
+
+// Supported backends.
+const (
+	BackendGitHub Backend = "github"
+	BackendGitLab Backend = "gitlab"
+	BackendGitea  Backend = "gitea"
+)
+
+// NewClient returns a Client for backend, authenticated with token. org is
+// the routing scope issues are listed across: an organization for GitHub,
+// a group for GitLab, or an organization for Gitea. baseURL overrides the
+// backend's default API endpoint, for GitLab/Gitea instances that aren't
+// gitlab.com or a public Gitea host; it is ignored for GitHub. The
+// returned Client already retries transient failures per
+// DefaultRetryPolicy — do not wrap it in another WithRetry, or its
+// retries will themselves be retried.
+func NewClient(ctx context.Context, backend Backend, org, token, baseURL string) (Client, error) {
+	client, err := newBackendClient(ctx, backend, org, token, baseURL)
+	if err != nil {
+		return nil, err
+	}
+	return WithRetry(client, DefaultRetryPolicy), nil
+}
+
+func newBackendClient(ctx context.Context, backend Backend, org, token, baseURL string) (Client, error) {
+	switch backend {
+	case BackendGitHub, "":
+		return NewGitHubClient(ctx, org, token), nil
+	case BackendGitLab:
+		return NewGitLabClient(org, token, baseURL)
+	case BackendGitea:
+		return NewGiteaClient(org, token, baseURL)
+	default:
+		return nil, fmt.Errorf("alerts: unknown backend %q", backend)
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2017 alertmanager-github-receiver Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//////////////////////////////////////////////////////////////////////////////
+package alerts
+
+import (
+	"testing"
+
+	"code.gitea.io/sdk/gitea"
+	"github.com/google/go-github/github"
+	gitlab "github.com/xanzy/go-gitlab"
+)
+
+// TestFromBackendIssue checks that every backend's native issue type
+// translates into the same backend-agnostic Issue, so ReceiverHandler can
+// treat them interchangeably.
+func TestFromBackendIssue(t *testing.T) {
+	tests := []struct {
+		name string
+		got  *Issue
+		want *Issue
+	}{
+		{
+			name: "github",
+			got: fromGitHubIssue(&github.Issue{
+				Number:        github.Int(42),
+				Title:         github.String("DiskRunningFull"),
+				Body:          github.String("body1"),
+				State:         github.String("open"),
+				RepositoryURL: github.String("https://api.github.com/repos/example/infra"),
+			}),
+			want: &Issue{Number: 42, Title: "DiskRunningFull", Body: "body1", State: "open", Repo: "infra"},
+		},
+		{
+			name: "gitlab",
+			got: fromGitLabIssue(&gitlab.Issue{
+				IID:         42,
+				Title:       "DiskRunningFull",
+				Description: "body1",
+				State:       "open",
+				ProjectID:   7,
+				References:  &gitlab.IssueReferences{Full: "example-group/infra#42"},
+			}),
+			want: &Issue{Number: 42, Title: "DiskRunningFull", Body: "body1", State: "open", Repo: "example-group/infra"},
+		},
+		{
+			name: "gitea",
+			got: fromGiteaIssue(&gitea.Issue{
+				Index: 42,
+				Title: "DiskRunningFull",
+				Body:  "body1",
+				State: gitea.StateOpen,
+				Repository: &gitea.RepositoryMeta{
+					Owner: "example",
+					Name:  "infra",
+				},
+			}),
+			want: &Issue{Number: 42, Title: "DiskRunningFull", Body: "body1", State: "open", Repo: "infra"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if *tt.got != *tt.want {
+				t.Errorf("from%sIssue() = %+v; want %+v", tt.name, tt.got, tt.want)
+			}
+		})
+	}
+}
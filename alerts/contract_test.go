@@ -0,0 +1,273 @@
+// Copyright 2017 alertmanager-github-receiver Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//////////////////////////////////////////////////////////////////////////////
+package alerts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// runClientContract exercises the sequence every Client implementation must
+// support: list the open issues the receiver already knows about, open a
+// new one, comment on it, and close it, with no error and with the fields
+// ReceiverHandler depends on round-tripping correctly. Every backend (and
+// any new one added later) is expected to pass this against its real wire
+// protocol, not just against the fake used in handler_test.go. wantRepo is
+// the Repo value ReceiverHandler should see on every Issue this Client
+// hands back, in whatever routing-key format that backend documents.
+func runClientContract(t *testing.T, client Client, repo, wantRepo string) {
+	t.Helper()
+
+	issues, err := client.ListOpenIssues()
+	if err != nil {
+		t.Fatalf("ListOpenIssues() error = %v", err)
+	}
+	if len(issues) != 1 || issues[0].Title != "ExistingIssue" {
+		t.Fatalf("ListOpenIssues() = %+v; want a single issue titled %q", issues, "ExistingIssue")
+	}
+	if issues[0].Repo != wantRepo {
+		t.Errorf("ListOpenIssues()[0].Repo = %q; want %q", issues[0].Repo, wantRepo)
+	}
+
+	created, err := client.CreateIssue(repo, "NewIssue", "issue body", []string{"alert"})
+	if err != nil {
+		t.Fatalf("CreateIssue() error = %v", err)
+	}
+	if created.Title != "NewIssue" || created.Body != "issue body" {
+		t.Errorf("CreateIssue() = %+v; want Title %q, Body %q", created, "NewIssue", "issue body")
+	}
+	if created.Repo != wantRepo {
+		t.Errorf("CreateIssue().Repo = %q; want %q", created.Repo, wantRepo)
+	}
+
+	if _, err := client.CommentOnIssue(created, "a comment"); err != nil {
+		t.Fatalf("CommentOnIssue() error = %v", err)
+	}
+
+	closed, err := client.CloseIssue(created)
+	if err != nil {
+		t.Fatalf("CloseIssue() error = %v", err)
+	}
+	if closed.State != "closed" {
+		t.Errorf("CloseIssue() State = %q; want %q", closed.State, "closed")
+	}
+	if closed.Repo != wantRepo {
+		t.Errorf("CloseIssue().Repo = %q; want %q", closed.Repo, wantRepo)
+	}
+}
+
+func TestGitHubClient_Contract(t *testing.T) {
+	const org, repo = "example", "infra"
+	mux := http.NewServeMux()
+	mux.HandleFunc(fmt.Sprintf("/orgs/%s/issues", org), func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, []map[string]interface{}{
+			{
+				"number":         1,
+				"title":          "ExistingIssue",
+				"body":           "body1",
+				"state":          "open",
+				"repository_url": fmt.Sprintf("https://api.github.com/repos/%s/%s", org, repo),
+			},
+		})
+	})
+	mux.HandleFunc(fmt.Sprintf("/repos/%s/%s/issues", org, repo), func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "want POST", http.StatusMethodNotAllowed)
+			return
+		}
+		var req struct {
+			Title string `json:"title"`
+			Body  string `json:"body"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		writeJSON(t, w, map[string]interface{}{
+			"number":         2,
+			"title":          req.Title,
+			"body":           req.Body,
+			"state":          "open",
+			"repository_url": fmt.Sprintf("https://api.github.com/repos/%s/%s", org, repo),
+		})
+	})
+	mux.HandleFunc(fmt.Sprintf("/repos/%s/%s/issues/2/comments", org, repo), func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, map[string]interface{}{"body": "a comment"})
+	})
+	mux.HandleFunc(fmt.Sprintf("/repos/%s/%s/issues/2", org, repo), func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch {
+			http.Error(w, "want PATCH", http.StatusMethodNotAllowed)
+			return
+		}
+		writeJSON(t, w, map[string]interface{}{
+			"number":         2,
+			"title":          "NewIssue",
+			"body":           "issue body",
+			"state":          "closed",
+			"repository_url": fmt.Sprintf("https://api.github.com/repos/%s/%s", org, repo),
+		})
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	client := NewGitHubClient(context.Background(), org, "dummy-token")
+	client.client.BaseURL, _ = url.Parse(srv.URL + "/")
+
+	runClientContract(t, client, repo, repo)
+}
+
+func TestGitLabClient_Contract(t *testing.T) {
+	const group, project = "example-group", "7"
+	mux := http.NewServeMux()
+	mux.HandleFunc(fmt.Sprintf("/api/v4/groups/%s/issues", group), func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, []map[string]interface{}{
+			{
+				"id": 1, "iid": 1, "title": "ExistingIssue", "description": "body1", "state": "opened", "project_id": 7,
+				"references": map[string]interface{}{"full": group + "/service-repo#1"},
+			},
+		})
+	})
+	mux.HandleFunc(fmt.Sprintf("/api/v4/projects/%s/issues", project), func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "want POST", http.StatusMethodNotAllowed)
+			return
+		}
+		var req struct {
+			Title       string `json:"title"`
+			Description string `json:"description"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		writeJSON(t, w, map[string]interface{}{
+			"id": 2, "iid": 2, "title": req.Title, "description": req.Description, "state": "opened", "project_id": 7,
+			"references": map[string]interface{}{"full": group + "/service-repo#2"},
+		})
+	})
+	// CommentOnIssue and CloseIssue address the issue via the Repo CreateIssue
+	// handed back, which is now the "group/project" path (see
+	// gitlab.References.Full below), not the project argument CreateIssue was
+	// originally called with above - exactly the round-trip this contract
+	// test exists to catch.
+	issuePath := group + "/service-repo"
+	mux.HandleFunc(fmt.Sprintf("/api/v4/projects/%s/issues/2/notes", issuePath), func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, map[string]interface{}{"id": 1, "body": "a comment"})
+	})
+	mux.HandleFunc(fmt.Sprintf("/api/v4/projects/%s/issues/2", issuePath), func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			http.Error(w, "want PUT", http.StatusMethodNotAllowed)
+			return
+		}
+		writeJSON(t, w, map[string]interface{}{
+			"id": 2, "iid": 2, "title": "NewIssue", "description": "issue body", "state": "closed", "project_id": 7,
+			"references": map[string]interface{}{"full": group + "/service-repo#2"},
+		})
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	client, err := NewGitLabClient(group, "dummy-token", srv.URL)
+	if err != nil {
+		t.Fatalf("NewGitLabClient() error = %v", err)
+	}
+
+	runClientContract(t, client, project, group+"/service-repo")
+}
+
+func TestGiteaClient_Contract(t *testing.T) {
+	const org, repo = "example", "infra"
+	mux := http.NewServeMux()
+	// gitea.NewClient checks the server's version before anything else can
+	// work, so /api/v1/version has to come up first or client construction
+	// itself fails.
+	mux.HandleFunc("/api/v1/version", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, map[string]interface{}{"version": "1.13.0"})
+	})
+	mux.HandleFunc("/api/v1/repos/issues/search", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") != "" && r.URL.Query().Get("page") != "1" {
+			writeJSON(t, w, []map[string]interface{}{})
+			return
+		}
+		writeJSON(t, w, []map[string]interface{}{
+			{
+				"number": 1,
+				"title":  "ExistingIssue",
+				"body":   "body1",
+				"state":  "open",
+				"repository": map[string]interface{}{
+					"id": 1, "name": repo, "owner": org, "full_name": org + "/" + repo,
+				},
+			},
+		})
+	})
+	mux.HandleFunc(fmt.Sprintf("/api/v1/repos/%s/%s/issues", org, repo), func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "want POST", http.StatusMethodNotAllowed)
+			return
+		}
+		var req struct {
+			Title string `json:"title"`
+			Body  string `json:"body"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		writeJSON(t, w, map[string]interface{}{
+			"number": 2,
+			"title":  req.Title,
+			"body":   req.Body,
+			"state":  "open",
+			"repository": map[string]interface{}{
+				"id": 1, "name": repo, "owner": org, "full_name": org + "/" + repo,
+			},
+		})
+	})
+	mux.HandleFunc(fmt.Sprintf("/api/v1/repos/%s/%s/issues/2/comments", org, repo), func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, map[string]interface{}{"id": 1, "body": "a comment"})
+	})
+	mux.HandleFunc(fmt.Sprintf("/api/v1/repos/%s/%s/issues/2", org, repo), func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch {
+			http.Error(w, "want PATCH", http.StatusMethodNotAllowed)
+			return
+		}
+		writeJSON(t, w, map[string]interface{}{
+			"number": 2,
+			"title":  "NewIssue",
+			"body":   "issue body",
+			"state":  "closed",
+			"repository": map[string]interface{}{
+				"id": 1, "name": repo, "owner": org, "full_name": org + "/" + repo,
+			},
+		})
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	client, err := NewGiteaClient(org, "dummy-token", srv.URL)
+	if err != nil {
+		t.Fatalf("NewGiteaClient() error = %v", err)
+	}
+
+	runClientContract(t, client, repo, repo)
+}
+
+func writeJSON(t *testing.T, w http.ResponseWriter, v interface{}) {
+	t.Helper()
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		t.Fatalf("encoding fake response: %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2017 alertmanager-github-receiver Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//////////////////////////////////////////////////////////////////////////////
+package alerts
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/alertmanager/template"
+)
+
+// fingerprintComment is the HTML comment prefix embedded in an issue body
+// that carries the alert group's fingerprint. Being an HTML comment, it
+// round-trips through GitHub's markdown rendering without being visible.
+const fingerprintPrefix = "alertmanager-fingerprint: "
+
+// fingerprintLabels are the label keys, beyond the Alertmanager GroupKey
+// itself, that are folded into an alert group's fingerprint. Keeping this
+// list short means label churn that doesn't change the underlying incident
+// (e.g. "instance") won't spawn a second issue for it.
+var fingerprintLabels = []string{"alertname", "repo", "severity"}
+
+// computeFingerprint derives a stable identifier for the incident behind a
+// webhook payload from its GroupKey and a small set of labels, so repeated
+// firings and resolutions of the same alert group land on one GitHub issue
+// instead of creating duplicates.
+func computeFingerprint(groupKey string, labels template.KV) string {
+	parts := make([]string, 0, len(fingerprintLabels))
+	for _, k := range fingerprintLabels {
+		if v, ok := labels[k]; ok {
+			parts = append(parts, k+"="+v)
+		}
+	}
+	sort.Strings(parts)
+	sum := sha256.Sum256([]byte(groupKey + "," + strings.Join(parts, ",")))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// renderFingerprintComment renders the HTML comment embedded in an issue
+// body for the given fingerprint.
+func renderFingerprintComment(fingerprint string) string {
+	return fmt.Sprintf("<!-- %s%s -->", fingerprintPrefix, fingerprint)
+}
+
+// parseFingerprint extracts the fingerprint embedded by
+// renderFingerprintComment from an issue body, returning "" if the body
+// carries none (e.g. an issue created before fingerprinting existed).
+func parseFingerprint(body string) string {
+	idx := strings.Index(body, fingerprintPrefix)
+	if idx == -1 {
+		return ""
+	}
+	rest := body[idx+len(fingerprintPrefix):]
+	end := strings.Index(rest, " -->")
+	if end == -1 {
+		return ""
+	}
+	return rest[:end]
+}
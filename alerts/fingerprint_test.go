@@ -0,0 +1,72 @@
+// Copyright 2017 alertmanager-github-receiver Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//////////////////////////////////////////////////////////////////////////////
+package alerts
+
+import (
+	"testing"
+
+	"github.com/prometheus/alertmanager/template"
+)
+
+func TestComputeFingerprint_RoundTripsThroughIssueBody(t *testing.T) {
+	fp := computeFingerprint(`{}:{alertname="DiskRunningFull"}`, template.KV{"alertname": "DiskRunningFull", "repo": "repoA"})
+	body := renderFingerprintComment(fp) + "\nsome rendered alert body"
+	if got := parseFingerprint(body); got != fp {
+		t.Errorf("parseFingerprint(renderFingerprintComment(%q)) = %q; want %q", fp, got, fp)
+	}
+}
+
+func TestComputeFingerprint_DiffersByRepo(t *testing.T) {
+	groupKey := `{}:{alertname="DiskRunningFull"}`
+	fpA := computeFingerprint(groupKey, template.KV{"alertname": "DiskRunningFull", "repo": "repoA"})
+	fpB := computeFingerprint(groupKey, template.KV{"alertname": "DiskRunningFull", "repo": "repoB"})
+	if fpA == fpB {
+		t.Errorf("computeFingerprint() = %q for both repoA and repoB; want distinct fingerprints for distinct incidents", fpA)
+	}
+}
+
+func TestFindMatchingIssue(t *testing.T) {
+	const groupKey = `{}:{alertname="DiskRunningFull"}`
+	fpA := computeFingerprint(groupKey, template.KV{"alertname": "DiskRunningFull", "repo": "repoA"})
+	fpB := computeFingerprint(groupKey, template.KV{"alertname": "DiskRunningFull", "repo": "repoB"})
+
+	t.Run("matches by fingerprint", func(t *testing.T) {
+		issue1 := &Issue{Title: "DiskRunningFull", Body: renderFingerprintComment(fpA)}
+		got := findMatchingIssue([]*Issue{issue1}, fpA, "DiskRunningFull")
+		if got != issue1 {
+			t.Errorf("findMatchingIssue() = %+v; want the issue carrying the matching fingerprint", got)
+		}
+	})
+
+	t.Run("falls back to title for pre-fingerprint issues", func(t *testing.T) {
+		issue1 := &Issue{Title: "DiskRunningFull", Body: "body with no fingerprint comment"}
+		got := findMatchingIssue([]*Issue{issue1}, fpB, "DiskRunningFull")
+		if got != issue1 {
+			t.Errorf("findMatchingIssue() = %+v; want the title fallback to match a pre-fingerprint issue", got)
+		}
+	})
+
+	t.Run("does not fall back to title for a different incident's fingerprinted issue", func(t *testing.T) {
+		// issue1 tracks repoA's DiskRunningFull incident (fingerprint fpA).
+		// repoB's DiskRunningFull incident (fingerprint fpB) fires with the
+		// same rendered title; it must get its own issue rather than being
+		// appended onto issue1 as a comment.
+		issue1 := &Issue{Title: "DiskRunningFull", Body: renderFingerprintComment(fpA)}
+		got := findMatchingIssue([]*Issue{issue1}, fpB, "DiskRunningFull")
+		if got != nil {
+			t.Errorf("findMatchingIssue() = %+v; want nil, since issue1 tracks a different incident's fingerprint", got)
+		}
+	})
+}
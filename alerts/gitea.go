@@ -0,0 +1,131 @@
+// Copyright 2017 alertmanager-github-receiver Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//////////////////////////////////////////////////////////////////////////////
+package alerts
+
+import (
+	"net/http"
+
+	"code.gitea.io/sdk/gitea"
+)
+
+// GiteaClient is the Client implementation backed by the Gitea issues API
+// for every repo under a single organization.
+type GiteaClient struct {
+	client *gitea.Client
+	org    string
+}
+
+// NewGiteaClient returns a GiteaClient authenticated with the given access
+// token, for creating and updating issues under org on the Gitea instance
+// at baseURL.
+func NewGiteaClient(org, token, baseURL string) (*GiteaClient, error) {
+	client, err := gitea.NewClient(baseURL, gitea.SetToken(token))
+	if err != nil {
+		return nil, err
+	}
+	return &GiteaClient{client: client, org: org}, nil
+}
+
+// ListOpenIssues returns all open issues across repos in the organization.
+func (c *GiteaClient) ListOpenIssues() ([]*Issue, error) {
+	opt := gitea.ListIssueOption{
+		State:       gitea.StateOpen,
+		ListOptions: gitea.ListOptions{Page: 1},
+	}
+	var all []*Issue
+	for {
+		issues, resp, err := c.client.ListIssues(opt)
+		if err != nil {
+			return nil, retryableGiteaError(err, resp)
+		}
+		if len(issues) == 0 {
+			break
+		}
+		for _, issue := range issues {
+			if issue.Repository == nil || issue.Repository.Owner != c.org {
+				continue
+			}
+			all = append(all, fromGiteaIssue(issue))
+		}
+		opt.ListOptions.Page++
+	}
+	return all, nil
+}
+
+// CreateIssue opens a new issue titled title in org/repo.
+func (c *GiteaClient) CreateIssue(repo, title, body string, extraLabels []string) (*Issue, error) {
+	opt := gitea.CreateIssueOption{
+		Title: title,
+		Body:  body,
+	}
+	issue, resp, err := c.client.CreateIssue(c.org, repo, opt)
+	if err != nil {
+		return nil, retryableGiteaError(err, resp)
+	}
+	return fromGiteaIssue(issue), nil
+}
+
+// CommentOnIssue appends body as a new comment on issue.
+func (c *GiteaClient) CommentOnIssue(issue *Issue, body string) (*Comment, error) {
+	opt := gitea.CreateIssueCommentOption{Body: body}
+	comment, resp, err := c.client.CreateIssueComment(c.org, issue.Repo, int64(issue.Number), opt)
+	if err != nil {
+		return nil, retryableGiteaError(err, resp)
+	}
+	return &Comment{Body: comment.Body}, nil
+}
+
+// CloseIssue marks issue as closed.
+func (c *GiteaClient) CloseIssue(issue *Issue) (*Issue, error) {
+	closed := gitea.StateClosed
+	opt := gitea.EditIssueOption{State: &closed}
+	edited, resp, err := c.client.EditIssue(c.org, issue.Repo, int64(issue.Number), opt)
+	if err != nil {
+		return nil, retryableGiteaError(err, resp)
+	}
+	return fromGiteaIssue(edited), nil
+}
+
+// retryableGiteaError wraps err in a RetryableError if resp indicates a
+// transient failure (a 5xx response, or 429 rate-limiting). The gitea SDK
+// returns plain errors with no structured status code classify can
+// recognize, unlike go-github and go-gitlab - exactly the case
+// RetryableError exists for.
+func retryableGiteaError(err error, resp *gitea.Response) error {
+	if resp == nil || resp.Response == nil {
+		return err
+	}
+	code := resp.StatusCode
+	if code >= 500 || code == http.StatusTooManyRequests {
+		return &RetryableError{Err: err, After: retryAfter(resp.Header)}
+	}
+	return err
+}
+
+// fromGiteaIssue translates a gitea SDK Issue into the backend-agnostic
+// Issue type the rest of the package works with.
+func fromGiteaIssue(issue *gitea.Issue) *Issue {
+	repo := ""
+	if issue.Repository != nil {
+		repo = issue.Repository.Name
+	}
+	return &Issue{
+		Number: int(issue.Index),
+		Title:  issue.Title,
+		Body:   issue.Body,
+		State:  string(issue.State),
+		Repo:   repo,
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2017 alertmanager-github-receiver Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//////////////////////////////////////////////////////////////////////////////
+package alerts
+
+import (
+	"context"
+
+	"github.com/google/go-github/github"
+	"golang.org/x/oauth2"
+)
+
+// GitHubClient is the Client implementation backed by the GitHub issues API
+// for a single organization.
+type GitHubClient struct {
+	client *github.Client
+	org    string
+}
+
+// NewGitHubClient returns a GitHubClient authenticated with the given
+// personal access token, for creating and updating issues in org.
+func NewGitHubClient(ctx context.Context, org, token string) *GitHubClient {
+	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
+	return &GitHubClient{
+		client: github.NewClient(oauth2.NewClient(ctx, ts)),
+		org:    org,
+	}
+}
+
+// ListOpenIssues returns all open issues created by the receiver across the
+// organization.
+func (c *GitHubClient) ListOpenIssues() ([]*Issue, error) {
+	opt := &github.IssueListOptions{
+		Filter: "all",
+		State:  "open",
+	}
+	var all []*Issue
+	for {
+		issues, resp, err := c.client.Issues.ListByOrg(context.Background(), c.org, opt)
+		if err != nil {
+			return nil, err
+		}
+		for _, issue := range issues {
+			all = append(all, fromGitHubIssue(issue))
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	return all, nil
+}
+
+// CreateIssue opens a new issue titled title in org/repo.
+func (c *GitHubClient) CreateIssue(repo, title, body string, extraLabels []string) (*Issue, error) {
+	req := &github.IssueRequest{
+		Title:  github.String(title),
+		Body:   github.String(body),
+		Labels: &extraLabels,
+	}
+	issue, _, err := c.client.Issues.Create(context.Background(), c.org, repo, req)
+	if err != nil {
+		return nil, err
+	}
+	return fromGitHubIssue(issue), nil
+}
+
+// CommentOnIssue appends body as a new comment on issue.
+func (c *GitHubClient) CommentOnIssue(issue *Issue, body string) (*Comment, error) {
+	comment, _, err := c.client.Issues.CreateComment(context.Background(), c.org, issue.Repo, issue.Number,
+		&github.IssueComment{Body: github.String(body)})
+	if err != nil {
+		return nil, err
+	}
+	return &Comment{Body: comment.GetBody()}, nil
+}
+
+// CloseIssue marks issue as closed.
+func (c *GitHubClient) CloseIssue(issue *Issue) (*Issue, error) {
+	req := &github.IssueRequest{State: github.String("closed")}
+	closed, _, err := c.client.Issues.Edit(context.Background(), c.org, issue.Repo, issue.Number, req)
+	if err != nil {
+		return nil, err
+	}
+	return fromGitHubIssue(closed), nil
+}
+
+// fromGitHubIssue translates a go-github Issue into the backend-agnostic
+// Issue type the rest of the package works with.
+func fromGitHubIssue(issue *github.Issue) *Issue {
+	return &Issue{
+		Number: issue.GetNumber(),
+		Title:  issue.GetTitle(),
+		Body:   issue.GetBody(),
+		State:  issue.GetState(),
+		Repo:   repoFromURL(issue.GetRepositoryURL()),
+	}
+}
+
+// repoFromURL extracts the bare repo name from a GitHub API repository URL,
+// which has the form "https://api.github.com/repos/<owner>/<repo>".
+func repoFromURL(url string) string {
+	for i := len(url) - 1; i >= 0; i-- {
+		if url[i] == '/' {
+			return url[i+1:]
+		}
+	}
+	return url
+}
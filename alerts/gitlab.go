@@ -0,0 +1,131 @@
+// Copyright 2017 alertmanager-github-receiver Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//////////////////////////////////////////////////////////////////////////////
+package alerts
+
+import (
+	"fmt"
+	"strings"
+
+	gitlab "github.com/xanzy/go-gitlab"
+)
+
+// GitLabClient is the Client implementation backed by the GitLab issues
+// API for every project under a single group.
+type GitLabClient struct {
+	client *gitlab.Client
+	group  string
+}
+
+// NewGitLabClient returns a GitLabClient authenticated with the given
+// personal access token, for creating and updating issues under group. If
+// baseURL is non-empty, it points the client at a self-managed GitLab
+// instance instead of gitlab.com.
+func NewGitLabClient(group, token, baseURL string) (*GitLabClient, error) {
+	var opts []gitlab.ClientOptionFunc
+	if baseURL != "" {
+		opts = append(opts, gitlab.WithBaseURL(baseURL))
+	}
+	client, err := gitlab.NewClient(token, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &GitLabClient{client: client, group: group}, nil
+}
+
+// ListOpenIssues returns all open issues in projects under the group.
+func (c *GitLabClient) ListOpenIssues() ([]*Issue, error) {
+	opened := "opened"
+	opt := &gitlab.ListGroupIssuesOptions{
+		State: &opened,
+	}
+	var all []*Issue
+	for {
+		issues, resp, err := c.client.Issues.ListGroupIssues(c.group, opt)
+		if err != nil {
+			return nil, err
+		}
+		for _, issue := range issues {
+			all = append(all, fromGitLabIssue(issue))
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	return all, nil
+}
+
+// CreateIssue opens a new issue titled title in the project identified by
+// repo (a "group/project" path, per GitLab's routing convention).
+func (c *GitLabClient) CreateIssue(repo, title, body string, extraLabels []string) (*Issue, error) {
+	opt := &gitlab.CreateIssueOptions{
+		Title:       &title,
+		Description: &body,
+		Labels:      gitlab.Labels(extraLabels),
+	}
+	issue, _, err := c.client.Issues.CreateIssue(repo, opt)
+	if err != nil {
+		return nil, err
+	}
+	return fromGitLabIssue(issue), nil
+}
+
+// CommentOnIssue appends body as a new note on issue.
+func (c *GitLabClient) CommentOnIssue(issue *Issue, body string) (*Comment, error) {
+	opt := &gitlab.CreateIssueNoteOptions{Body: &body}
+	note, _, err := c.client.Notes.CreateIssueNote(issue.Repo, issue.Number, opt)
+	if err != nil {
+		return nil, err
+	}
+	return &Comment{Body: note.Body}, nil
+}
+
+// CloseIssue marks issue as closed.
+func (c *GitLabClient) CloseIssue(issue *Issue) (*Issue, error) {
+	closeAction := "close"
+	opt := &gitlab.UpdateIssueOptions{StateEvent: &closeAction}
+	closed, _, err := c.client.Issues.UpdateIssue(issue.Repo, issue.Number, opt)
+	if err != nil {
+		return nil, err
+	}
+	return fromGitLabIssue(closed), nil
+}
+
+// fromGitLabIssue translates a go-gitlab Issue into the backend-agnostic
+// Issue type the rest of the package works with.
+func fromGitLabIssue(issue *gitlab.Issue) *Issue {
+	return &Issue{
+		Number: issue.IID,
+		Title:  issue.Title,
+		Body:   issue.Description,
+		State:  issue.State,
+		Repo:   gitlabProjectPath(issue),
+	}
+}
+
+// gitlabProjectPath returns the "group/project" path issue belongs to, in
+// the same format CreateIssue's repo argument and the "repo" webhook label
+// use, so logs and metrics read consistently across backends. GitLab only
+// surfaces this on the issue itself via References.Full (e.g.
+// "group/project#42"); fall back to the bare numeric project ID on the rare
+// response that omits it, since go-gitlab's pid parameter accepts either.
+func gitlabProjectPath(issue *gitlab.Issue) string {
+	if issue.References != nil && issue.References.Full != "" {
+		if i := strings.LastIndex(issue.References.Full, "#"); i >= 0 {
+			return issue.References.Full[:i]
+		}
+	}
+	return fmt.Sprintf("%d", issue.ProjectID)
+}
@@ -0,0 +1,192 @@
+// Copyright 2017 alertmanager-github-receiver Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//////////////////////////////////////////////////////////////////////////////
+package alerts
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+)
+
+// ReceiverHandler implements http.Handler and turns Alertmanager webhook
+// notifications into tracker issues via Client.
+type ReceiverHandler struct {
+	Client Client
+
+	// AutoClose closes an issue's tracked alert group once Alertmanager
+	// reports it resolved. When false, resolved notifications are only
+	// recorded as a comment and the issue is left for a human to close.
+	AutoClose bool
+
+	// DefaultRepo is the repo issues are filed in when a webhook payload
+	// does not carry a "repo" common label.
+	DefaultRepo string
+
+	// ExtraLabels are applied to every issue this handler creates, in
+	// addition to any label GitHub infers from the repo's defaults.
+	ExtraLabels []string
+
+	// Renderer renders each issue's title and status-update body. A nil
+	// Renderer falls back to DefaultRenderer.
+	Renderer *Renderer
+
+	// HMACSecret, if set, requires every request to carry a valid
+	// "X-Hub-Signature-256" header computed over the raw body, the same
+	// scheme GitHub itself uses to sign outgoing webhooks. Takes
+	// precedence over BearerToken and PathToken.
+	HMACSecret string
+
+	// BearerToken, if set (and HMACSecret is not), requires every request
+	// to carry "Authorization: Bearer <BearerToken>".
+	BearerToken string
+
+	// PathToken, if set (and HMACSecret and BearerToken are not), requires
+	// the final path segment of the request URL to equal PathToken, so
+	// distinct Alertmanager instances can share a receiver at
+	// "/v1/receiver/<token>" with distinct credentials.
+	PathToken string
+}
+
+// renderer returns rh.Renderer, or DefaultRenderer if none was configured.
+func (rh *ReceiverHandler) renderer() *Renderer {
+	if rh.Renderer != nil {
+		return rh.Renderer
+	}
+	return DefaultRenderer
+}
+
+// ServeHTTP authenticates an Alertmanager webhook POST (see auth.go),
+// matches its alert group against any open issue tracking the same
+// incident (by fingerprint, see fingerprint.go), and creates or updates a
+// GitHub issue accordingly. It records webhook_handling_seconds and
+// webhooks_received_total for every request (see metrics.go).
+func (rh *ReceiverHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	status := rh.serve(r)
+	webhookHandlingSeconds.Observe(time.Since(start).Seconds())
+	webhooksReceived.WithLabelValues(strconv.Itoa(status)).Inc()
+	w.WriteHeader(status)
+}
+
+// serve does the actual work of ServeHTTP, returning the HTTP status code
+// to respond with.
+func (rh *ReceiverHandler) serve(r *http.Request) int {
+	if r.Method != http.MethodPost {
+		return http.StatusMethodNotAllowed
+	}
+
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return http.StatusInternalServerError
+	}
+
+	if code := rh.authenticate(r, data); code != 0 {
+		return code
+	}
+
+	msg := &notify.WebhookMessage{}
+	if err := json.Unmarshal(data, msg); err != nil {
+		return http.StatusBadRequest
+	}
+
+	issues, err := rh.Client.ListOpenIssues()
+	if err != nil {
+		slog.Error("list open issues failed", "err", err)
+		issueOperationErrors.WithLabelValues("list").Inc()
+		return http.StatusInternalServerError
+	}
+
+	rh.handleMessage(msg, issues)
+	return http.StatusOK
+}
+
+// handleMessage matches msg against issues and opens, comments on, or
+// closes a tracker issue as appropriate. Errors from the Client, and from
+// rendering the issue's title/body, never fail the request: the webhook
+// has already been accepted, and ServeHTTP's 200 just means "the
+// notification was understood", not "every downstream operation
+// succeeded". Client errors are still logged and counted in
+// issue_operations_errors_total so they're visible to an operator.
+func (rh *ReceiverHandler) handleMessage(msg *notify.WebhookMessage, issues []*Issue) {
+	title, _ := rh.renderer().Title(msg.Data)
+	fingerprint := computeFingerprint(msg.GroupKey, msg.Data.CommonLabels)
+	existing := findMatchingIssue(issues, fingerprint, title)
+	alertname := msg.Data.CommonLabels["alertname"]
+
+	switch msg.Data.Status {
+	case "resolved":
+		if existing == nil {
+			return
+		}
+		body, _ := rh.renderer().Body(msg.Data)
+		if _, err := rh.Client.CommentOnIssue(existing, body); err != nil {
+			slog.Error("comment on issue failed", "alertname", alertname, "groupKey", msg.GroupKey, "repo", existing.Repo, "err", err)
+			issueOperationErrors.WithLabelValues("comment").Inc()
+		}
+		if rh.AutoClose {
+			if _, err := rh.Client.CloseIssue(existing); err != nil {
+				slog.Error("close issue failed", "alertname", alertname, "groupKey", msg.GroupKey, "repo", existing.Repo, "err", err)
+				issueOperationErrors.WithLabelValues("close").Inc()
+				return
+			}
+			slog.Info("closed issue", "alertname", alertname, "groupKey", msg.GroupKey, "repo", existing.Repo)
+			issuesClosed.WithLabelValues(existing.Repo).Inc()
+		}
+	default: // "firing"
+		body, _ := rh.renderer().Body(msg.Data)
+		if existing != nil {
+			if _, err := rh.Client.CommentOnIssue(existing, body); err != nil {
+				slog.Error("comment on issue failed", "alertname", alertname, "groupKey", msg.GroupKey, "repo", existing.Repo, "err", err)
+				issueOperationErrors.WithLabelValues("comment").Inc()
+			}
+			return
+		}
+		repo := rh.DefaultRepo
+		if v, ok := msg.Data.CommonLabels["repo"]; ok && v != "" {
+			repo = v
+		}
+		if _, err := rh.Client.CreateIssue(repo, title, renderFingerprintComment(fingerprint)+"\n"+body, rh.ExtraLabels); err != nil {
+			slog.Error("create issue failed", "alertname", alertname, "groupKey", msg.GroupKey, "repo", repo, "err", err)
+			issueOperationErrors.WithLabelValues("create").Inc()
+			return
+		}
+		slog.Info("created issue", "alertname", alertname, "groupKey", msg.GroupKey, "repo", repo)
+		issuesCreated.WithLabelValues(repo).Inc()
+	}
+}
+
+// findMatchingIssue returns the open issue tracking fingerprint, if any. As
+// a fallback for issues created before fingerprinting existed, it also
+// matches on the issue title equaling title, but only among issues with no
+// fingerprint of their own - an issue that already carries a fingerprint is
+// tracking a specific incident and must never be matched by title alone.
+func findMatchingIssue(issues []*Issue, fingerprint, title string) *Issue {
+	for _, issue := range issues {
+		if parseFingerprint(issue.Body) == fingerprint {
+			return issue
+		}
+	}
+	for _, issue := range issues {
+		if parseFingerprint(issue.Body) == "" && issue.Title == title {
+			return issue
+		}
+	}
+	return nil
+}
@@ -4,7 +4,7 @@
 // you may not use this file except in compliance with the License.
 // You may obtain a copy of the License at
 //
-//     http://www.apache.org/licenses/LICENSE-2.0
+//	http://www.apache.org/licenses/LICENSE-2.0
 //
 // Unless required by applicable law or agreed to in writing, software
 // distributed under the License is distributed on an "AS IS" BASIS,
@@ -12,10 +12,18 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 //////////////////////////////////////////////////////////////////////////////
-package alerts
+
+// Package alerts_test exercises ReceiverHandler as a black box, against a
+// counterfeiter-generated fake Client (see alertsfakes). It lives in its own
+// package, rather than alerts, because alertsfakes imports alerts and an
+// in-package test importing it back would be a cycle.
+package alerts_test
 
 import (
 	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -25,36 +33,21 @@ import (
 	"testing"
 	"time"
 
-	"github.com/google/go-github/github"
 	"github.com/prometheus/alertmanager/notify"
 	"github.com/prometheus/alertmanager/template"
-)
-
-type fakeClient struct {
-	listIssues   []*github.Issue
-	createdIssue *github.Issue
-	closedIssue  *github.Issue
-	listError    error
-}
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 
-func (f *fakeClient) ListOpenIssues() ([]*github.Issue, error) {
-	fmt.Println("list open issues")
-	if f.listError != nil {
-		return nil, f.listError
-	}
-	return f.listIssues, nil
-}
-
-func (f *fakeClient) CreateIssue(repo, title, body string, extra []string) (*github.Issue, error) {
-	fmt.Println("create issue")
-	f.createdIssue = createIssue(title, body, repo)
-	return f.createdIssue, nil
-}
+	"github.com/hemajv/alertmanager-github-receiver/alerts"
+	"github.com/hemajv/alertmanager-github-receiver/alerts/alertsfakes"
+)
 
-func (f *fakeClient) CloseIssue(issue *github.Issue) (*github.Issue, error) {
-	fmt.Println("close issue")
-	f.closedIssue = issue
-	return issue, nil
+// hmacHexDigest returns the hex-encoded HMAC-SHA256 of body under secret,
+// matching what validSignature expects after the "sha256=" prefix.
+func hmacHexDigest(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
 }
 
 func createWebhookMessage(alertname, status, repo string) *notify.WebhookMessage {
@@ -89,13 +82,10 @@ func marshalWebhookMessage(msg *notify.WebhookMessage) *bytes.Buffer {
 	return bytes.NewBuffer(b)
 }
 
-func createIssue(title, body, repo string) *github.Issue {
-	return &github.Issue{
-		Title:         github.String(title),
-		Body:          github.String(body),
-		RepositoryURL: github.String(repo),
-	}
-}
+// validHMACSentinel, used as a test case's authValue, tells the test loop to
+// compute a real HMAC-SHA256 signature over the marshaled request body
+// rather than sending authValue verbatim.
+const validHMACSentinel = "<valid-hmac>"
 
 type errorReader struct {
 }
@@ -104,27 +94,75 @@ func (e *errorReader) Read(p []byte) (n int, err error) {
 	return 0, fmt.Errorf("Fake error")
 }
 
+// counterValue returns the current value of the single-label counter
+// metricName{labelName=labelValue}, as scraped from the default Prometheus
+// registry ReceiverHandler registers its metrics with. It returns 0 if the
+// series hasn't been observed yet, the same as a fresh CounterVec would.
+func counterValue(t *testing.T, metricName, labelName, labelValue string) float64 {
+	t.Helper()
+	mfs, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	for _, mf := range mfs {
+		if mf.GetName() != metricName {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			if labelMatches(m, labelName, labelValue) {
+				return m.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
+
+func labelMatches(m *dto.Metric, name, value string) bool {
+	for _, lp := range m.GetLabel() {
+		if lp.GetName() == name {
+			return lp.GetValue() == value
+		}
+	}
+	return false
+}
+
 func TestReceiverHandler_ServeHTTP(t *testing.T) {
 	tests := []struct {
-		name           string
-		method         string
-		msgAlert       string
-		msgAlertStatus string
-		msgRepo        string
-		fakeClient     *fakeClient
-		httpStatus     int
-		wantMessageErr bool
-		wantReadErr    bool
+		name            string
+		method          string
+		msgAlert        string
+		msgAlertStatus  string
+		msgRepo         string
+		wantClient      bool
+		listIssues      []*alerts.Issue
+		listError       error
+		createError     error
+		commentError    error
+		closeError      error
+		httpStatus      int
+		wantMessageErr  bool
+		wantReadErr     bool
+		wantCommentOnly bool
+		wantCreateOnly  bool
+		// wantErrOp, if set, asserts that issue_operations_errors_total{op=
+		// wantErrOp} increments by 1 over the course of this request.
+		wantErrOp string
+
+		reqPath     string
+		hmacSecret  string
+		bearerToken string
+		pathToken   string
+		authHeader  string
+		authValue   string
 	}{
 		{
 			name:           "successful-close",
 			method:         http.MethodPost,
 			msgAlert:       "DiskRunningFull",
 			msgAlertStatus: "resolved",
-			fakeClient: &fakeClient{
-				listIssues: []*github.Issue{
-					createIssue("DiskRunningFull", "body1", ""),
-				},
+			wantClient:     true,
+			listIssues: []*alerts.Issue{
+				{Title: "DiskRunningFull", Body: "body1"},
 			},
 			httpStatus: http.StatusOK,
 		},
@@ -133,7 +171,7 @@ func TestReceiverHandler_ServeHTTP(t *testing.T) {
 			method:         http.MethodPost,
 			msgAlert:       "DiskRunningFull",
 			msgAlertStatus: "firing",
-			fakeClient:     &fakeClient{},
+			wantClient:     true,
 			httpStatus:     http.StatusOK,
 		},
 		{
@@ -142,20 +180,36 @@ func TestReceiverHandler_ServeHTTP(t *testing.T) {
 			msgAlert:       "DiskRunningFull",
 			msgAlertStatus: "firing",
 			msgRepo:        "custom-repo",
-			fakeClient:     &fakeClient{},
+			wantClient:     true,
 			httpStatus:     http.StatusOK,
 		},
 		{
-			name:           "successful-ignore-existing-issue-for-firing-alert",
+			name:           "successful-comment-on-existing-issue-for-flapping-alert",
 			method:         http.MethodPost,
 			msgAlert:       "DiskRunningFull",
 			msgAlertStatus: "firing",
-			fakeClient: &fakeClient{
-				listIssues: []*github.Issue{
-					createIssue("DiskRunningFull", "body1", ""),
-				},
+			wantClient:     true,
+			listIssues: []*alerts.Issue{
+				{Title: "DiskRunningFull", Body: "body1"},
 			},
-			httpStatus: http.StatusOK,
+			httpStatus:      http.StatusOK,
+			wantCommentOnly: true,
+		},
+		{
+			name:           "successful-create-for-different-incident-with-same-title",
+			method:         http.MethodPost,
+			msgAlert:       "DiskRunningFull",
+			msgAlertStatus: "firing",
+			msgRepo:        "repoB",
+			wantClient:     true,
+			listIssues: []*alerts.Issue{
+				// Tracks repoA's DiskRunningFull incident; the fingerprint
+				// comment makes it ineligible for repoB's title-only
+				// fallback match below.
+				{Title: "DiskRunningFull", Body: "<!-- alertmanager-fingerprint: deadbeefcafe -->\nbody1", Repo: "repoA"},
+			},
+			httpStatus:     http.StatusOK,
+			wantCreateOnly: true,
 		},
 		{
 			name:           "failure-unmarshal-error",
@@ -170,18 +224,117 @@ func TestReceiverHandler_ServeHTTP(t *testing.T) {
 			wantReadErr: true,
 		},
 		{
-			name:   "failure-list-error",
-			method: http.MethodPost,
-			fakeClient: &fakeClient{
-				listError: fmt.Errorf("Fake error listing current issues"),
-			},
+			name:       "failure-list-error",
+			method:     http.MethodPost,
+			wantClient: true,
+			listError:  fmt.Errorf("Fake error listing current issues"),
 			httpStatus: http.StatusInternalServerError,
 		},
+		{
+			name:           "failure-create-issue-error",
+			method:         http.MethodPost,
+			msgAlert:       "DiskRunningFull",
+			msgAlertStatus: "firing",
+			wantClient:     true,
+			createError:    fmt.Errorf("fake create error"),
+			httpStatus:     http.StatusOK,
+			wantErrOp:      "create",
+		},
+		{
+			name:           "failure-comment-on-issue-error",
+			method:         http.MethodPost,
+			msgAlert:       "DiskRunningFull",
+			msgAlertStatus: "firing",
+			wantClient:     true,
+			listIssues: []*alerts.Issue{
+				{Title: "DiskRunningFull", Body: "body1"},
+			},
+			commentError: fmt.Errorf("fake comment error"),
+			httpStatus:   http.StatusOK,
+			wantErrOp:    "comment",
+		},
+		{
+			name:           "failure-close-issue-error",
+			method:         http.MethodPost,
+			msgAlert:       "DiskRunningFull",
+			msgAlertStatus: "resolved",
+			wantClient:     true,
+			listIssues: []*alerts.Issue{
+				{Title: "DiskRunningFull", Body: "body1"},
+			},
+			closeError: fmt.Errorf("fake close error"),
+			httpStatus: http.StatusOK,
+			wantErrOp:  "close",
+		},
 		{
 			name:       "failure-wrong-method",
 			method:     http.MethodGet,
 			httpStatus: http.StatusMethodNotAllowed,
 		},
+		{
+			name:           "failure-hmac-bad-signature",
+			method:         http.MethodPost,
+			msgAlert:       "DiskRunningFull",
+			msgAlertStatus: "firing",
+			wantClient:     true,
+			hmacSecret:     "shared-secret",
+			authHeader:     "X-Hub-Signature-256",
+			authValue:      "sha256=0000000000000000000000000000000000000000000000000000000000000000",
+			httpStatus:     http.StatusForbidden,
+		},
+		{
+			name:           "successful-hmac-valid-signature",
+			method:         http.MethodPost,
+			msgAlert:       "DiskRunningFull",
+			msgAlertStatus: "firing",
+			wantClient:     true,
+			hmacSecret:     "shared-secret",
+			authHeader:     "X-Hub-Signature-256",
+			authValue:      validHMACSentinel,
+			httpStatus:     http.StatusOK,
+		},
+		{
+			name:           "failure-bearer-wrong-token",
+			method:         http.MethodPost,
+			msgAlert:       "DiskRunningFull",
+			msgAlertStatus: "firing",
+			wantClient:     true,
+			bearerToken:    "token123",
+			authHeader:     "Authorization",
+			authValue:      "Bearer wrong",
+			httpStatus:     http.StatusUnauthorized,
+		},
+		{
+			name:           "successful-bearer-valid-token",
+			method:         http.MethodPost,
+			msgAlert:       "DiskRunningFull",
+			msgAlertStatus: "firing",
+			wantClient:     true,
+			bearerToken:    "token123",
+			authHeader:     "Authorization",
+			authValue:      "Bearer token123",
+			httpStatus:     http.StatusOK,
+		},
+		{
+			name:           "failure-path-token-mismatch",
+			method:         http.MethodPost,
+			msgAlert:       "DiskRunningFull",
+			msgAlertStatus: "firing",
+			wantClient:     true,
+			pathToken:      "abc123",
+			reqPath:        "/v1/receiver/wrong",
+			httpStatus:     http.StatusUnauthorized,
+		},
+		{
+			name:           "successful-path-token-match",
+			method:         http.MethodPost,
+			msgAlert:       "DiskRunningFull",
+			msgAlertStatus: "firing",
+			wantClient:     true,
+			pathToken:      "abc123",
+			reqPath:        "/v1/receiver/abc123",
+			httpStatus:     http.StatusOK,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -203,18 +356,67 @@ func TestReceiverHandler_ServeHTTP(t *testing.T) {
 			// Create a response recorder.
 			rw := httptest.NewRecorder()
 			// Create a synthetic request that sends an alertmanager webhook message.
-			req, err := http.NewRequest(tt.method, "/v1/receiver", msgReader)
+			reqPath := tt.reqPath
+			if reqPath == "" {
+				reqPath = "/v1/receiver"
+			}
+			req, err := http.NewRequest(tt.method, reqPath, msgReader)
 			if err != nil {
 				t.Fatal(err)
 				return
 			}
+			if tt.authHeader != "" {
+				authValue := tt.authValue
+				if authValue == validHMACSentinel {
+					authValue = "sha256=" + hmacHexDigest(tt.hmacSecret, msg.Bytes())
+				}
+				req.Header.Set(tt.authHeader, authValue)
+			}
 
-			rh := &ReceiverHandler{
-				Client:      tt.fakeClient,
+			// client is nil unless tt.wantClient opts in, matching
+			// scenarios (bad method, unmarshal/read errors, failed auth)
+			// where ServeHTTP returns before ever touching the Client.
+			var client *alertsfakes.FakeClient
+			if tt.wantClient {
+				client = &alertsfakes.FakeClient{}
+				client.ListOpenIssuesReturns(tt.listIssues, tt.listError)
+				client.CreateIssueReturns(nil, tt.createError)
+				client.CommentOnIssueReturns(nil, tt.commentError)
+				client.CloseIssueReturns(nil, tt.closeError)
+			}
+
+			rh := &alerts.ReceiverHandler{
 				AutoClose:   true,
 				DefaultRepo: "default",
 				ExtraLabels: nil,
+				HMACSecret:  tt.hmacSecret,
+				BearerToken: tt.bearerToken,
+				PathToken:   tt.pathToken,
+			}
+			if client != nil {
+				rh.Client = client
+			}
+
+			// Snapshot the counters this scenario is expected to move, so
+			// the assertions below check the delta from this one request
+			// rather than the cumulative total across the whole test run.
+			statusLabel := fmt.Sprint(tt.httpStatus)
+			beforeReceived := counterValue(t, "webhooks_received_total", "status", statusLabel)
+			createdRepo := tt.msgRepo
+			if createdRepo == "" {
+				createdRepo = "default"
+			}
+			beforeCreated := counterValue(t, "issues_created_total", "repo", createdRepo)
+			var closedRepo string
+			if len(tt.listIssues) > 0 {
+				closedRepo = tt.listIssues[0].Repo
+			}
+			beforeClosed := counterValue(t, "issues_closed_total", "repo", closedRepo)
+			var beforeErrOp float64
+			if tt.wantErrOp != "" {
+				beforeErrOp = counterValue(t, "issue_operations_errors_total", "op", tt.wantErrOp)
 			}
+
 			rh.ServeHTTP(rw, req)
 			resp := rw.Result()
 
@@ -223,20 +425,56 @@ func TestReceiverHandler_ServeHTTP(t *testing.T) {
 			if resp.StatusCode != tt.httpStatus {
 				t.Errorf("ReceiverHandler got %d; want %d", resp.StatusCode, tt.httpStatus)
 			}
-			if tt.fakeClient != nil && tt.fakeClient.closedIssue != nil {
-				if *tt.fakeClient.closedIssue.Title != tt.msgAlert {
+			if got := counterValue(t, "webhooks_received_total", "status", statusLabel) - beforeReceived; got != 1 {
+				t.Errorf("webhooks_received_total{status=%q} increased by %v; want 1", statusLabel, got)
+			}
+			if tt.wantErrOp != "" {
+				if got := counterValue(t, "issue_operations_errors_total", "op", tt.wantErrOp) - beforeErrOp; got != 1 {
+					t.Errorf("issue_operations_errors_total{op=%q} increased by %v; want 1", tt.wantErrOp, got)
+				}
+			}
+			if client != nil && client.CloseIssueCallCount() > 0 {
+				closed := client.CloseIssueArgsForCall(0)
+				if closed.Title != tt.msgAlert {
 					t.Errorf("ReceiverHandler closed wrong issue; got %q want %q",
-						*tt.fakeClient.closedIssue.Title, tt.msgAlert)
+						closed.Title, tt.msgAlert)
+				}
+				if tt.closeError == nil {
+					if got := counterValue(t, "issues_closed_total", "repo", closedRepo) - beforeClosed; got != 1 {
+						t.Errorf("issues_closed_total{repo=%q} increased by %v; want 1", closedRepo, got)
+					}
 				}
 			}
-			if tt.fakeClient != nil && tt.fakeClient.createdIssue != nil {
-				if *tt.fakeClient.createdIssue.Title != tt.msgAlert {
+			if client != nil && client.CreateIssueCallCount() > 0 {
+				repo, title, _, _ := client.CreateIssueArgsForCall(0)
+				if title != tt.msgAlert {
 					t.Errorf("ReceiverHandler created wrong issue; got %q want %q",
-						*tt.fakeClient.createdIssue.Title, tt.msgAlert)
+						title, tt.msgAlert)
+				}
+				if tt.createError == nil {
+					if got := counterValue(t, "issues_created_total", "repo", createdRepo) - beforeCreated; got != 1 {
+						t.Errorf("issues_created_total{repo=%q} increased by %v; want 1", createdRepo, got)
+					}
 				}
-				if tt.msgRepo != "" && *tt.fakeClient.createdIssue.RepositoryURL != tt.msgRepo {
+				if tt.msgRepo != "" && repo != tt.msgRepo {
 					t.Errorf("ReceiverHandler created wrong repo; got %q want %q",
-						*tt.fakeClient.createdIssue.RepositoryURL, tt.msgRepo)
+						repo, tt.msgRepo)
+				}
+			}
+			if tt.wantCommentOnly {
+				if client.CommentOnIssueCallCount() != 1 {
+					t.Errorf("ReceiverHandler commented on the existing issue %d times; want 1", client.CommentOnIssueCallCount())
+				}
+				if client.CreateIssueCallCount() != 0 {
+					t.Errorf("ReceiverHandler created a duplicate issue instead of commenting on the existing one")
+				}
+			}
+			if tt.wantCreateOnly {
+				if client.CreateIssueCallCount() != 1 {
+					t.Errorf("ReceiverHandler created %d issues; want 1", client.CreateIssueCallCount())
+				}
+				if client.CommentOnIssueCallCount() != 0 {
+					t.Errorf("ReceiverHandler commented on an unrelated issue instead of opening a new one for this incident")
 				}
 			}
 			if string(body) != "" {
@@ -245,3 +483,36 @@ func TestReceiverHandler_ServeHTTP(t *testing.T) {
 		})
 	}
 }
+
+// TestReceiverHandler_CallSequence checks a scenario the old hand-written
+// fakeClient couldn't express: that a flapping alert's second "firing"
+// notification calls ListOpenIssues then CommentOnIssue, in that order,
+// with the existing issue, and never calls CreateIssue.
+func TestReceiverHandler_CallSequence(t *testing.T) {
+	client := &alertsfakes.FakeClient{}
+	existing := &alerts.Issue{Title: "DiskRunningFull", Body: "body1", Repo: "default"}
+	client.ListOpenIssuesReturns([]*alerts.Issue{existing}, nil)
+
+	rh := &alerts.ReceiverHandler{Client: client, AutoClose: true, DefaultRepo: "default"}
+	req, err := http.NewRequest(http.MethodPost, "/v1/receiver",
+		marshalWebhookMessage(createWebhookMessage("DiskRunningFull", "firing", "")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rh.ServeHTTP(httptest.NewRecorder(), req)
+
+	invocations := client.Invocations()
+	if n := len(invocations["ListOpenIssues"]); n != 1 {
+		t.Errorf("ListOpenIssues called %d times; want 1", n)
+	}
+	if n := client.CommentOnIssueCallCount(); n != 1 {
+		t.Fatalf("CommentOnIssue called %d times; want 1", n)
+	}
+	if n := client.CreateIssueCallCount(); n != 0 {
+		t.Errorf("CreateIssue called %d times; want 0, since the issue already exists", n)
+	}
+	commented, _ := client.CommentOnIssueArgsForCall(0)
+	if commented != existing {
+		t.Errorf("CommentOnIssue called with %+v; want the existing issue %+v", commented, existing)
+	}
+}
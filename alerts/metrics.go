@@ -0,0 +1,56 @@
+// Copyright 2017 alertmanager-github-receiver Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//////////////////////////////////////////////////////////////////////////////
+package alerts
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Metrics tracked for every webhook ReceiverHandler serves. They're
+// registered with the default registry on package init, so a binary need
+// only expose promhttp.Handler() to serve them.
+var (
+	webhooksReceived = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "webhooks_received_total",
+		Help: "Number of Alertmanager webhook requests received, by response status code.",
+	}, []string{"status"})
+
+	issuesCreated = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "issues_created_total",
+		Help: "Number of tracker issues created, by repo.",
+	}, []string{"repo"})
+
+	issuesClosed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "issues_closed_total",
+		Help: "Number of tracker issues closed, by repo.",
+	}, []string{"repo"})
+
+	issueOperationErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "issue_operations_errors_total",
+		Help: "Number of failed Client operations, by operation: list, create, comment, close.",
+	}, []string{"op"})
+
+	retriesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "retries_total",
+		Help: "Number of retried Client operations due to a transient failure, by operation: list, create, close.",
+	}, []string{"op"})
+
+	webhookHandlingSeconds = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name: "webhook_handling_seconds",
+		Help: "Time spent handling a single Alertmanager webhook request.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(webhooksReceived, issuesCreated, issuesClosed, issueOperationErrors, webhookHandlingSeconds, retriesTotal)
+}
@@ -0,0 +1,208 @@
+// Copyright 2017 alertmanager-github-receiver Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//////////////////////////////////////////////////////////////////////////////
+package alerts
+
+import (
+	"errors"
+	"log/slog"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/go-github/github"
+	"github.com/xanzy/go-gitlab"
+)
+
+// RetryPolicy configures the exponential backoff WithRetry applies to
+// transient Client failures: 5xx responses, 403 rate-limiting, and network
+// errors.
+type RetryPolicy struct {
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+	MaxElapsedTime  time.Duration
+	Multiplier      float64
+}
+
+// DefaultRetryPolicy is the policy NewClient applies: up to a minute of
+// retries, backing off from half a second up to 30 seconds between
+// attempts.
+var DefaultRetryPolicy = RetryPolicy{
+	InitialInterval: 500 * time.Millisecond,
+	MaxInterval:     30 * time.Second,
+	MaxElapsedTime:  time.Minute,
+	Multiplier:      2,
+}
+
+// RetryableError marks Err as a transient failure that WithRetry should
+// retry. Client implementations that can't be classified by statusCode
+// (fakes, or backends with no structured HTTP error type) return this to
+// opt in to retries. After, if nonzero, is honored as the wait before the
+// next attempt, analogous to a Retry-After or X-RateLimit-Reset header.
+type RetryableError struct {
+	Err   error
+	After time.Duration
+}
+
+func (e *RetryableError) Error() string { return e.Err.Error() }
+func (e *RetryableError) Unwrap() error { return e.Err }
+
+// retryingClient wraps a Client, retrying ListOpenIssues, CreateIssue,
+// CommentOnIssue, and CloseIssue with exponential backoff on transient
+// failures.
+type retryingClient struct {
+	Client
+	policy RetryPolicy
+}
+
+// WithRetry wraps client so its ListOpenIssues, CreateIssue, CommentOnIssue,
+// and CloseIssue calls are retried per policy on transient failures (5xx
+// responses, 403 rate limiting, and network errors), honoring any
+// Retry-After or X-RateLimit-Reset header the backend reports.
+func WithRetry(client Client, policy RetryPolicy) Client {
+	return &retryingClient{Client: client, policy: policy}
+}
+
+func (c *retryingClient) ListOpenIssues() ([]*Issue, error) {
+	var issues []*Issue
+	err := c.run("list", func() error {
+		var err error
+		issues, err = c.Client.ListOpenIssues()
+		return err
+	})
+	return issues, err
+}
+
+func (c *retryingClient) CreateIssue(repo, title, body string, extraLabels []string) (*Issue, error) {
+	var issue *Issue
+	err := c.run("create", func() error {
+		var err error
+		issue, err = c.Client.CreateIssue(repo, title, body, extraLabels)
+		return err
+	})
+	return issue, err
+}
+
+func (c *retryingClient) CommentOnIssue(issue *Issue, body string) (*Comment, error) {
+	var comment *Comment
+	err := c.run("comment", func() error {
+		var err error
+		comment, err = c.Client.CommentOnIssue(issue, body)
+		return err
+	})
+	return comment, err
+}
+
+func (c *retryingClient) CloseIssue(issue *Issue) (*Issue, error) {
+	var closed *Issue
+	err := c.run("close", func() error {
+		var err error
+		closed, err = c.Client.CloseIssue(issue)
+		return err
+	})
+	return closed, err
+}
+
+// run calls fn, retrying with exponential backoff while its error is
+// transient and c.policy.MaxElapsedTime hasn't elapsed.
+func (c *retryingClient) run(op string, fn func() error) error {
+	interval := c.policy.InitialInterval
+	deadline := time.Now().Add(c.policy.MaxElapsedTime)
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		retryable, after := classify(err)
+		if !retryable || !time.Now().Before(deadline) {
+			return err
+		}
+
+		wait := after
+		if wait == 0 {
+			wait = jitter(interval)
+		}
+		slog.Warn("retrying transient Client error", "op", op, "attempt", attempt, "wait", wait, "err", err)
+		retriesTotal.WithLabelValues(op).Inc()
+		time.Sleep(wait)
+
+		interval = time.Duration(float64(interval) * c.policy.Multiplier)
+		if interval > c.policy.MaxInterval {
+			interval = c.policy.MaxInterval
+		}
+	}
+}
+
+// classify reports whether err is a transient failure worth retrying, and
+// any server-suggested wait before the next attempt.
+func classify(err error) (retryable bool, after time.Duration) {
+	var re *RetryableError
+	if errors.As(err, &re) {
+		return true, re.After
+	}
+	if code, header, ok := statusCode(err); ok {
+		return code >= 500 || code == http.StatusForbidden, retryAfter(header)
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true, 0
+	}
+	return false, 0
+}
+
+// statusCode extracts the HTTP status code and response headers from a
+// go-github or go-gitlab error, if err is one of those.
+func statusCode(err error) (code int, header http.Header, ok bool) {
+	var ghErr *github.ErrorResponse
+	if errors.As(err, &ghErr) && ghErr.Response != nil {
+		return ghErr.Response.StatusCode, ghErr.Response.Header, true
+	}
+	var glErr *gitlab.ErrorResponse
+	if errors.As(err, &glErr) && glErr.Response != nil {
+		return glErr.Response.StatusCode, glErr.Response.Header, true
+	}
+	return 0, nil, false
+}
+
+// retryAfter honors a Retry-After or X-RateLimit-Reset response header, if
+// present, returning 0 if neither is set or parseable.
+func retryAfter(header http.Header) time.Duration {
+	if header == nil {
+		return 0
+	}
+	if v := header.Get("Retry-After"); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil {
+			return time.Duration(secs) * time.Second
+		}
+	}
+	if v := header.Get("X-RateLimit-Reset"); v != "" {
+		if unix, err := strconv.ParseInt(v, 10, 64); err == nil {
+			if d := time.Until(time.Unix(unix, 0)); d > 0 {
+				return d
+			}
+		}
+	}
+	return 0
+}
+
+// jitter returns a random duration in [d/2, d), so concurrent retries
+// after an outage don't all retry in lockstep.
+func jitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d)/2+1))
+}
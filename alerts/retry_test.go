@@ -0,0 +1,313 @@
+// Copyright 2017 alertmanager-github-receiver Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//////////////////////////////////////////////////////////////////////////////
+package alerts
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/github"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/xanzy/go-gitlab"
+)
+
+// createIssue builds a backend-agnostic Issue fixture for tests in this
+// package that need a Client to hand back a plausible result.
+func createIssue(title, body, repo string) *Issue {
+	return &Issue{
+		Title: title,
+		Body:  body,
+		Repo:  repo,
+	}
+}
+
+// createWebhookMessage builds a minimal Alertmanager webhook payload for a
+// single alert named alertname, used by tests that drive a ReceiverHandler
+// end to end.
+func createWebhookMessage(alertname, status, repo string) *notify.WebhookMessage {
+	msg := &notify.WebhookMessage{
+		Data: &template.Data{
+			Receiver: "webhook",
+			Status:   status,
+			Alerts: template.Alerts{
+				template.Alert{
+					Status:       status,
+					Labels:       template.KV{"dev": "sda3", "instance": "example4", "alertname": alertname},
+					Annotations:  template.KV{"description": "This is how to handle the alert"},
+					StartsAt:     time.Unix(1498614000, 0),
+					GeneratorURL: "http://generator.url/",
+				},
+			},
+			GroupLabels:  template.KV{"alertname": alertname},
+			CommonLabels: template.KV{"alertname": alertname, "repo": repo},
+			ExternalURL:  "http://localhost:9093",
+		},
+		Version:  "4",
+		GroupKey: fmt.Sprintf("{}:{alertname=\"%s\"}", alertname),
+	}
+	if status == "resolved" {
+		msg.Data.Alerts[0].EndsAt = time.Unix(1498618000, 0)
+	}
+	return msg
+}
+
+// flakyClient fails its first failures calls to each method, then succeeds.
+type flakyClient struct {
+	failures     int
+	listCalls    int
+	commentCalls int
+}
+
+func (f *flakyClient) ListOpenIssues() ([]*Issue, error) {
+	f.listCalls++
+	if f.listCalls <= f.failures {
+		return nil, &RetryableError{Err: fmt.Errorf("fake 503")}
+	}
+	return nil, nil
+}
+
+func (f *flakyClient) CreateIssue(repo, title, body string, extraLabels []string) (*Issue, error) {
+	return createIssue(title, body, repo), nil
+}
+
+func (f *flakyClient) CommentOnIssue(issue *Issue, body string) (*Comment, error) {
+	f.commentCalls++
+	if f.commentCalls <= f.failures {
+		return nil, &RetryableError{Err: fmt.Errorf("fake 503")}
+	}
+	return &Comment{Body: body}, nil
+}
+
+func (f *flakyClient) CloseIssue(issue *Issue) (*Issue, error) {
+	return issue, nil
+}
+
+// testPolicy retries fast enough for a test to not be slow, but still
+// exercises the real backoff loop in retryingClient.run.
+var testPolicy = RetryPolicy{
+	InitialInterval: time.Millisecond,
+	MaxInterval:     5 * time.Millisecond,
+	MaxElapsedTime:  time.Second,
+	Multiplier:      2,
+}
+
+func TestWithRetry_SucceedsAfterTransientFailures(t *testing.T) {
+	fake := &flakyClient{failures: 2}
+	client := WithRetry(fake, testPolicy)
+
+	before := testutil.ToFloat64(retriesTotal.WithLabelValues("list"))
+	if _, err := client.ListOpenIssues(); err != nil {
+		t.Fatalf("ListOpenIssues() error = %v; want nil after retrying", err)
+	}
+	if fake.listCalls != 3 {
+		t.Errorf("ListOpenIssues() called the underlying Client %d times; want 3", fake.listCalls)
+	}
+	if got := testutil.ToFloat64(retriesTotal.WithLabelValues("list")) - before; got != 2 {
+		t.Errorf("retries_total{op=\"list\"} increased by %v; want 2", got)
+	}
+}
+
+func TestWithRetry_CommentOnIssue_SucceedsAfterTransientFailures(t *testing.T) {
+	fake := &flakyClient{failures: 2}
+	client := WithRetry(fake, testPolicy)
+
+	before := testutil.ToFloat64(retriesTotal.WithLabelValues("comment"))
+	if _, err := client.CommentOnIssue(createIssue("DiskRunningFull", "body1", "repo"), "a comment"); err != nil {
+		t.Fatalf("CommentOnIssue() error = %v; want nil after retrying", err)
+	}
+	if fake.commentCalls != 3 {
+		t.Errorf("CommentOnIssue() called the underlying Client %d times; want 3", fake.commentCalls)
+	}
+	if got := testutil.ToFloat64(retriesTotal.WithLabelValues("comment")) - before; got != 2 {
+		t.Errorf("retries_total{op=\"comment\"} increased by %v; want 2", got)
+	}
+}
+
+func TestWithRetry_GivesUpAfterMaxElapsedTime(t *testing.T) {
+	fake := &flakyClient{failures: 1000}
+	client := WithRetry(fake, RetryPolicy{
+		InitialInterval: time.Millisecond,
+		MaxInterval:     2 * time.Millisecond,
+		MaxElapsedTime:  10 * time.Millisecond,
+		Multiplier:      2,
+	})
+
+	if _, err := client.ListOpenIssues(); err == nil {
+		t.Fatalf("ListOpenIssues() error = nil; want a transient failure to surface once retries are exhausted")
+	}
+}
+
+// fakeHTTPResponse builds a minimal *http.Response with a Request attached,
+// since github.ErrorResponse.Error() and gitlab.ErrorResponse.Error() both
+// dereference it when formatting their message.
+func fakeHTTPResponse(statusCode int, header http.Header) *http.Response {
+	req, _ := http.NewRequest(http.MethodGet, "https://example.test/", nil)
+	return &http.Response{
+		Request:    req,
+		StatusCode: statusCode,
+		Header:     header,
+	}
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           error
+		wantRetryable bool
+	}{
+		{
+			name:          "retryable-error-wrapper",
+			err:           &RetryableError{Err: fmt.Errorf("fake 503")},
+			wantRetryable: true,
+		},
+		{
+			name:          "plain-error-not-retryable",
+			err:           fmt.Errorf("fake unmarshal error"),
+			wantRetryable: false,
+		},
+		{
+			name:          "github-5xx-retryable",
+			err:           &github.ErrorResponse{Response: fakeHTTPResponse(http.StatusBadGateway, nil)},
+			wantRetryable: true,
+		},
+		{
+			name:          "github-rate-limited-retryable",
+			err:           &github.ErrorResponse{Response: fakeHTTPResponse(http.StatusForbidden, nil)},
+			wantRetryable: true,
+		},
+		{
+			name:          "github-404-not-retryable",
+			err:           &github.ErrorResponse{Response: fakeHTTPResponse(http.StatusNotFound, nil)},
+			wantRetryable: false,
+		},
+		{
+			name:          "gitlab-5xx-retryable",
+			err:           &gitlab.ErrorResponse{Response: fakeHTTPResponse(http.StatusServiceUnavailable, nil)},
+			wantRetryable: true,
+		},
+		{
+			name:          "gitlab-404-not-retryable",
+			err:           &gitlab.ErrorResponse{Response: fakeHTTPResponse(http.StatusNotFound, nil)},
+			wantRetryable: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			retryable, _ := classify(tt.err)
+			if retryable != tt.wantRetryable {
+				t.Errorf("classify(%v) retryable = %v; want %v", tt.err, retryable, tt.wantRetryable)
+			}
+		})
+	}
+}
+
+// TestClassify_HonorsRetryAfter checks that a 5xx error classified as
+// retryable also carries the Retry-After header's wait through to classify's
+// after return value, the same way RetryableError.After does.
+func TestClassify_HonorsRetryAfter(t *testing.T) {
+	header := http.Header{"Retry-After": []string{"2"}}
+	err := &github.ErrorResponse{Response: fakeHTTPResponse(http.StatusServiceUnavailable, header)}
+
+	retryable, after := classify(err)
+	if !retryable {
+		t.Fatalf("classify(%v) retryable = false; want true", err)
+	}
+	if after != 2*time.Second {
+		t.Errorf("classify(%v) after = %v; want 2s", err, after)
+	}
+}
+
+// TestGiteaClient_RetriesTransientErrors checks that a Gitea backend's 5xx
+// and 429 responses come back wrapped in RetryableError, since gitea.Client
+// returns plain errors with no structured status code classify can
+// recognize on its own (see retryableGiteaError in gitea.go).
+func TestGiteaClient_RetriesTransientErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		statusCode    int
+		wantRetryable bool
+	}{
+		{name: "server-error", statusCode: http.StatusServiceUnavailable, wantRetryable: true},
+		{name: "rate-limited", statusCode: http.StatusTooManyRequests, wantRetryable: true},
+		{name: "not-found", statusCode: http.StatusNotFound, wantRetryable: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/api/v1/version", func(w http.ResponseWriter, r *http.Request) {
+				json.NewEncoder(w).Encode(map[string]string{"version": "1.13.0"})
+			})
+			mux.HandleFunc("/api/v1/repos/issues/search", func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "fake failure", tt.statusCode)
+			})
+			srv := httptest.NewServer(mux)
+			defer srv.Close()
+
+			client, err := NewGiteaClient("example", "dummy-token", srv.URL)
+			if err != nil {
+				t.Fatalf("NewGiteaClient() error = %v", err)
+			}
+
+			_, err = client.ListOpenIssues()
+			if err == nil {
+				t.Fatalf("ListOpenIssues() error = nil; want the fake %d failure", tt.statusCode)
+			}
+			retryable, _ := classify(err)
+			if retryable != tt.wantRetryable {
+				t.Errorf("classify(%v) retryable = %v; want %v", err, retryable, tt.wantRetryable)
+			}
+		})
+	}
+}
+
+// TestReceiverHandler_RetriesTransientListErrors checks that a
+// ReceiverHandler wired up with WithRetry (as NewClient's callers get by
+// default) rides out a flaky Client instead of surfacing a 500, and
+// records the retries in retries_total.
+func TestReceiverHandler_RetriesTransientListErrors(t *testing.T) {
+	fake := &flakyClient{failures: 2}
+	rh := &ReceiverHandler{
+		Client:      WithRetry(fake, testPolicy),
+		AutoClose:   true,
+		DefaultRepo: "default",
+	}
+	body, _ := json.Marshal(createWebhookMessage("DiskRunningFull", "firing", ""))
+	req, err := http.NewRequest(http.MethodPost, "/v1/receiver", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rw := httptest.NewRecorder()
+
+	before := testutil.ToFloat64(retriesTotal.WithLabelValues("list"))
+	rh.ServeHTTP(rw, req)
+	resp := rw.Result()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("ServeHTTP() status = %d; want %d once the Client recovers", resp.StatusCode, http.StatusOK)
+	}
+	if fake.listCalls != 3 {
+		t.Errorf("ListOpenIssues() called %d times; want 3 (2 failures + 1 success)", fake.listCalls)
+	}
+	if got := testutil.ToFloat64(retriesTotal.WithLabelValues("list")) - before; got != 2 {
+		t.Errorf("retries_total{op=\"list\"} increased by %v; want 2", got)
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2017 alertmanager-github-receiver Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//////////////////////////////////////////////////////////////////////////////
+package alerts
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/prometheus/alertmanager/template"
+)
+
+// DefaultTitleTemplate names each issue after the alert group it tracks.
+const DefaultTitleTemplate = `{{ .CommonLabels.alertname }}`
+
+// DefaultBodyTemplate renders a markdown status update: a severity emoji,
+// the common labels as a table, and a row per alert instance with its
+// description and, if set, a link to its "runbook_url" annotation.
+const DefaultBodyTemplate = `{{ severityEmoji .CommonLabels.severity }} **{{ .Status | toUpper }}**
+
+{{ labelsToTable .CommonLabels }}
+| Alert | Description | Runbook |
+| --- | --- | --- |
+{{- range .Alerts }}
+| {{ index .Labels "alertname" }} | {{ index .Annotations "description" }} | {{ with index .Annotations "runbook_url" }}[Runbook]({{ . }}){{ end }} |
+{{- end }}
+`
+
+// templateFuncs are available to any title or body template.
+var templateFuncs = texttemplate.FuncMap{
+	"toUpper":       strings.ToUpper,
+	"join":          strings.Join,
+	"trimPrefix":    func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+	"labelsToTable": labelsToTable,
+	"severityEmoji": severityEmoji,
+}
+
+// severityEmoji maps a "severity" label to a glyph that makes an issue's
+// urgency visible at a glance, falling back to a neutral bullet for
+// unrecognized or missing values.
+func severityEmoji(severity string) string {
+	switch severity {
+	case "critical":
+		return "🔴"
+	case "page":
+		return "🚨"
+	case "warning":
+		return "🟡"
+	default:
+		return "⚪"
+	}
+}
+
+// labelsToTable renders a label set as a two-column markdown table, with
+// keys sorted for a stable rendering.
+func labelsToTable(labels template.KV) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString("| Label | Value |\n| --- | --- |\n")
+	for _, k := range keys {
+		fmt.Fprintf(&b, "| %s | %s |\n", k, labels[k])
+	}
+	return b.String()
+}
+
+// Renderer renders an alert group's issue title and body from a pair of Go
+// templates, so operators can control issue formatting without touching
+// the receiver's code. Use NewRenderer to build one from template source;
+// DefaultRenderer covers the common case.
+type Renderer struct {
+	title *texttemplate.Template
+	body  *texttemplate.Template
+}
+
+// DefaultRenderer renders issues with DefaultTitleTemplate and
+// DefaultBodyTemplate.
+var DefaultRenderer = mustNewRenderer(DefaultTitleTemplate, DefaultBodyTemplate)
+
+// NewRenderer parses titleTmpl and bodyTmpl as text/template source,
+// exposing templateFuncs, and returns a Renderer that executes them
+// against a webhook's *template.Data.
+func NewRenderer(titleTmpl, bodyTmpl string) (*Renderer, error) {
+	title, err := texttemplate.New("title").Funcs(templateFuncs).Parse(titleTmpl)
+	if err != nil {
+		return nil, fmt.Errorf("parsing title template: %w", err)
+	}
+	body, err := texttemplate.New("body").Funcs(templateFuncs).Parse(bodyTmpl)
+	if err != nil {
+		return nil, fmt.Errorf("parsing body template: %w", err)
+	}
+	return &Renderer{title: title, body: body}, nil
+}
+
+func mustNewRenderer(titleTmpl, bodyTmpl string) *Renderer {
+	r, err := NewRenderer(titleTmpl, bodyTmpl)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// Title renders data's issue title.
+func (r *Renderer) Title(data *template.Data) (string, error) {
+	var b bytes.Buffer
+	if err := r.title.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// Body renders data's status update body.
+func (r *Renderer) Body(data *template.Data) (string, error) {
+	var b bytes.Buffer
+	if err := r.body.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
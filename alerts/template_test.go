@@ -0,0 +1,80 @@
+// Copyright 2017 alertmanager-github-receiver Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//////////////////////////////////////////////////////////////////////////////
+package alerts
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderer_DefaultTemplate(t *testing.T) {
+	data := createWebhookMessage("DiskRunningFull", "firing", "infra").Data
+	data.CommonLabels["severity"] = "critical"
+	data.Alerts[0].Annotations["runbook_url"] = "http://runbooks/disk-full"
+
+	title, err := DefaultRenderer.Title(data)
+	if err != nil {
+		t.Fatalf("Title() error = %v", err)
+	}
+	if title != "DiskRunningFull" {
+		t.Errorf("Title() = %q; want %q", title, "DiskRunningFull")
+	}
+
+	body, err := DefaultRenderer.Body(data)
+	if err != nil {
+		t.Fatalf("Body() error = %v", err)
+	}
+	for _, want := range []string{"🔴", "FIRING", "This is how to handle the alert", "http://runbooks/disk-full"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Body() = %q; want it to contain %q", body, want)
+		}
+	}
+}
+
+func TestRenderer_CustomTemplate(t *testing.T) {
+	data := createWebhookMessage("DiskRunningFull", "firing", "infra").Data
+
+	r, err := NewRenderer(
+		`{{ trimPrefix "Disk" .CommonLabels.alertname | toUpper }}`,
+		`status={{ .Status | toUpper }} repo={{ join (list .CommonLabels.repo) "," }}`,
+	)
+	if err == nil {
+		t.Fatalf("NewRenderer() with an undefined \"list\" func should have failed to parse")
+	}
+
+	r, err = NewRenderer(
+		`{{ trimPrefix "Disk" .CommonLabels.alertname | toUpper }}`,
+		`status={{ .Status | toUpper }}`,
+	)
+	if err != nil {
+		t.Fatalf("NewRenderer() error = %v", err)
+	}
+
+	title, err := r.Title(data)
+	if err != nil {
+		t.Fatalf("Title() error = %v", err)
+	}
+	if title != "RUNNINGFULL" {
+		t.Errorf("Title() = %q; want %q", title, "RUNNINGFULL")
+	}
+
+	body, err := r.Body(data)
+	if err != nil {
+		t.Fatalf("Body() error = %v", err)
+	}
+	if body != "status=FIRING" {
+		t.Errorf("Body() = %q; want %q", body, "status=FIRING")
+	}
+}
@@ -0,0 +1,25 @@
+// Copyright 2017 alertmanager-github-receiver Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//////////////////////////////////////////////////////////////////////////////
+
+//go:build tools
+
+// Package tools records build-time tool dependencies in go.mod so `go
+// generate` always runs a version pinned by go.sum, without pulling the
+// tool into the main build. See https://github.com/golang/go/issues/25922.
+package tools
+
+import (
+	_ "github.com/maxbrunsfeld/counterfeiter/v6/generator"
+)
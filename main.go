@@ -0,0 +1,134 @@
+// Copyright 2017 alertmanager-github-receiver Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//////////////////////////////////////////////////////////////////////////////
+
+// Command alertmanager-github-receiver runs an HTTP server that turns
+// Alertmanager webhook notifications into tracker issues.
+package main
+
+import (
+	"context"
+	"flag"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/hemajv/alertmanager-github-receiver/alerts"
+)
+
+var (
+	listenAddr = flag.String("addr", ":9393", "Address to listen on for Alertmanager webhooks.")
+	backend    = flag.String("backend", "github", "Issue tracker backend to file issues in: github, gitlab, or gitea.")
+	baseURL    = flag.String("base-url", "", "API base URL for the backend, for self-managed GitLab/Gitea instances. Ignored for github.")
+
+	org         = flag.String("org", "", "Organization (or GitLab group) to file issues in.")
+	githubToken = flag.String("github.token", "", "GitHub personal access token. Used when --backend=github.")
+	gitlabToken = flag.String("gitlab.token", "", "GitLab personal access token. Used when --backend=gitlab.")
+	giteaToken  = flag.String("gitea.token", "", "Gitea access token. Used when --backend=gitea.")
+
+	defaultRepo = flag.String("default-repo", "", "Repo to file issues in when a webhook payload has no \"repo\" label.")
+	autoClose   = flag.Bool("auto-close", true, "Automatically close an issue once Alertmanager reports it resolved.")
+	extraLabels = flag.String("extra-labels", "", "Comma-separated list of labels applied to every issue this receiver creates.")
+
+	titleTemplate     = flag.String("title-template", "", "Inline Go template for issue titles. Overrides the default; see --title-template-file to load one from disk.")
+	titleTemplateFile = flag.String("title-template-file", "", "Path to a Go template file for issue titles.")
+	bodyTemplate      = flag.String("body-template", "", "Inline Go template for issue bodies. Overrides the default; see --body-template-file to load one from disk.")
+	bodyTemplateFile  = flag.String("body-template-file", "", "Path to a Go template file for issue bodies.")
+
+	hmacSecret  = flag.String("hmac-secret", "", "Shared secret used to verify the \"X-Hub-Signature-256\" header on incoming webhooks.")
+	bearerToken = flag.String("bearer-token", "", "Require this bearer token in incoming requests' Authorization header. Ignored if --hmac-secret is set.")
+	pathToken   = flag.String("path-token", "", "Require this token as the final path segment of incoming requests, e.g. /v1/receiver/<token>. Ignored if --hmac-secret or --bearer-token is set.")
+)
+
+func main() {
+	flag.Parse()
+
+	client, err := alerts.NewClient(context.Background(), alerts.Backend(*backend), *org, backendToken(), *baseURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	renderer, err := newRenderer()
+	if err != nil {
+		log.Fatal(err)
+	}
+	rh := &alerts.ReceiverHandler{
+		Client:      client,
+		AutoClose:   *autoClose,
+		DefaultRepo: *defaultRepo,
+		ExtraLabels: splitLabels(*extraLabels),
+		Renderer:    renderer,
+		HMACSecret:  *hmacSecret,
+		BearerToken: *bearerToken,
+		PathToken:   *pathToken,
+	}
+
+	http.Handle("/v1/receiver", rh)
+	http.Handle("/v1/receiver/", rh)
+	http.Handle("/metrics", promhttp.Handler())
+	log.Printf("Listening on %s, filing issues via %s", *listenAddr, *backend)
+	log.Fatal(http.ListenAndServe(*listenAddr, nil))
+}
+
+// backendToken returns the auth token flag matching --backend.
+func backendToken() string {
+	switch alerts.Backend(*backend) {
+	case alerts.BackendGitLab:
+		return *gitlabToken
+	case alerts.BackendGitea:
+		return *giteaToken
+	default:
+		return *githubToken
+	}
+}
+
+func splitLabels(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+// newRenderer builds the issue Renderer from the --title-template(-file)
+// and --body-template(-file) flags, falling back to alerts.DefaultRenderer
+// for any template the operator didn't override.
+func newRenderer() (*alerts.Renderer, error) {
+	title, err := templateSource(*titleTemplate, *titleTemplateFile, alerts.DefaultTitleTemplate)
+	if err != nil {
+		return nil, err
+	}
+	body, err := templateSource(*bodyTemplate, *bodyTemplateFile, alerts.DefaultBodyTemplate)
+	if err != nil {
+		return nil, err
+	}
+	return alerts.NewRenderer(title, body)
+}
+
+// templateSource resolves a template's source, preferring an inline flag
+// over a file path over def.
+func templateSource(inline, path, def string) (string, error) {
+	if inline != "" {
+		return inline, nil
+	}
+	if path != "" {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	}
+	return def, nil
+}